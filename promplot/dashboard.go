@@ -0,0 +1,162 @@
+package promplot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+	"github.com/prometheus/common/model"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Number of data points requested per dashboard panel.
+const dashboardStep = 100
+
+// PanelConfig describes a single query rendered within a dashboard.
+type PanelConfig struct {
+	Title   string   `yaml:"title" json:"title"`
+	Query   string   `yaml:"query" json:"query"`
+	Range   string   `yaml:"range" json:"range"`
+	Unit    string   `yaml:"unit,omitempty" json:"unit,omitempty"`
+	YMin    *float64 `yaml:"y_min,omitempty" json:"y_min,omitempty"`
+	YMax    *float64 `yaml:"y_max,omitempty" json:"y_max,omitempty"`
+	Stacked bool     `yaml:"stacked,omitempty" json:"stacked,omitempty"`
+}
+
+// DashboardConfig describes a multi-panel status report: a grid of
+// independently-queried panels rendered onto a single composite image.
+type DashboardConfig struct {
+	Title  string        `yaml:"title" json:"title"`
+	Cols   int           `yaml:"cols" json:"cols"`
+	Panels []PanelConfig `yaml:"panels" json:"panels"`
+}
+
+// ParseDashboardConfig parses a dashboard config in YAML (or JSON, which
+// is valid YAML) format.
+func ParseDashboardConfig(data []byte) (DashboardConfig, error) {
+	var cfg DashboardConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return DashboardConfig{}, fmt.Errorf("failed parsing dashboard config: %v", err)
+	}
+	if len(cfg.Panels) == 0 {
+		return DashboardConfig{}, fmt.Errorf("dashboard config has no panels")
+	}
+	return cfg, nil
+}
+
+// PlotDashboard queries every panel in cfg against promServer and
+// arranges them in a cfg.Cols-wide grid on a single composite image.
+// This lets a single cron invocation produce a status report with e.g.
+// CPU, memory, request rate and error rate panels, instead of one
+// query per invocation.
+func PlotDashboard(promServer string, cfg DashboardConfig, queryTime time.Time, format string, logger log.Logger) (io.Reader, error) {
+	if len(cfg.Panels) == 0 {
+		return nil, fmt.Errorf("dashboard config has no panels")
+	}
+	cols := cfg.Cols
+	if cols <= 0 {
+		cols = 1
+	}
+	rows := (len(cfg.Panels) + cols - 1) / cols
+
+	panelWidth := 24 * vg.Centimeter
+	panelHeight := 16 * vg.Centimeter
+	width := panelWidth * vg.Length(cols)
+	height := panelHeight * vg.Length(rows)
+
+	c, err := draw.NewFormattedCanvas(width, height, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating image canvas: %v", err)
+	}
+
+	for i, panel := range cfg.Panels {
+		duration, err := ParseRange(panel.Range)
+		if err != nil {
+			return nil, fmt.Errorf("panel %q: invalid range: %v", panel.Title, err)
+		}
+
+		queryStart := time.Now()
+		metrics, err := Metrics(promServer, panel.Query, queryTime, duration, dashboardStep)
+		ObserveQuery(err)
+		if err != nil {
+			return nil, fmt.Errorf("panel %q: failed getting metrics: %v", panel.Title, err)
+		}
+		level.Debug(logger).Log("msg", "panel query done", "panel", panel.Title, "query", panel.Query, "series", len(metrics), "duration_ms", time.Since(queryStart).Milliseconds())
+
+		renderStart := time.Now()
+		p, err := newPanelPlot(metrics, panel)
+		ObserveRender(renderStart, err)
+		if err != nil {
+			return nil, fmt.Errorf("panel %q: %v", panel.Title, err)
+		}
+		level.Debug(logger).Log("msg", "panel render done", "panel", panel.Title, "duration_ms", time.Since(renderStart).Milliseconds())
+
+		row, col := i/cols, i%cols
+		tile := draw.Canvas{
+			Canvas: c,
+			Rectangle: vg.Rectangle{
+				Min: vg.Point{X: panelWidth * vg.Length(col), Y: height - panelHeight*vg.Length(row+1)},
+				Max: vg.Point{X: panelWidth * vg.Length(col+1), Y: height - panelHeight*vg.Length(row)},
+			},
+		}
+		margin := 4 * vg.Millimeter
+		p.Draw(draw.Crop(tile, margin, -margin, margin, -margin))
+	}
+
+	b := new(bytes.Buffer)
+	if _, err := c.WriteTo(b); err != nil {
+		return nil, fmt.Errorf("failed saving dashboard: %v", err)
+	}
+	return b, nil
+}
+
+// newPanelPlot builds the plot for a single dashboard panel, applying
+// its axis unit, y-range and stacking on top of the shared styling.
+func newPanelPlot(metrics model.Matrix, panel PanelConfig) (*plot.Plot, error) {
+	p, err := newStyledPlot(panel.Title)
+	if err != nil {
+		return nil, err
+	}
+	if panel.Unit != "" {
+		p.Y.Label.Text = panel.Unit
+	}
+
+	if panel.Stacked {
+		stackMatrix(metrics)
+	}
+
+	if _, err := addLines(p, metrics, len(metrics) > 1); err != nil {
+		return nil, err
+	}
+
+	if panel.YMin != nil {
+		p.Y.Min = *panel.YMin
+	}
+	if panel.YMax != nil {
+		p.Y.Max = *panel.YMax
+	}
+	return p, nil
+}
+
+// stackMatrix turns each sample's values into a cumulative sum across
+// samples at the same index, so that panels with Stacked set render
+// each line on top of the ones before it rather than overlapping them.
+// Only the lines are drawn; the area below them is not filled. It assumes samples
+// share the same timestamps, which holds since every sample in a panel
+// comes from the same range/step query.
+func stackMatrix(metrics model.Matrix) {
+	for i := 1; i < len(metrics); i++ {
+		for j := range metrics[i].Values {
+			if j >= len(metrics[i-1].Values) {
+				break
+			}
+			metrics[i].Values[j].Value += metrics[i-1].Values[j].Value
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package promplot
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus metrics instrumenting promplot itself. These matter once
+// promplot runs as a long-lived -http server or -schedule process
+// rather than a one-shot CLI.
+var (
+	queriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "promplot_queries_total",
+		Help: "Total number of Prometheus queries, by status.",
+	}, []string{"status"})
+
+	renderSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "promplot_render_seconds",
+		Help:    "Time spent rendering a plot.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	lastRenderTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "promplot_last_render_timestamp_seconds",
+		Help: "Unix timestamp of the last successful render.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(queriesTotal, renderSeconds, lastRenderTimestamp)
+}
+
+// ObserveQuery records the outcome of a call to Metrics.
+func ObserveQuery(err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	queriesTotal.WithLabelValues(status).Inc()
+}
+
+// ObserveRender records how long rendering took (a call to Plot, or
+// building a single panel within PlotDashboard), updating the last
+// successful render timestamp on success. The query
+// isn't used as a label: in -http mode it's caller-supplied and
+// unbounded, which would otherwise blow up the metric's cardinality.
+func ObserveRender(start time.Time, err error) {
+	renderSeconds.Observe(time.Since(start).Seconds())
+	if err == nil {
+		lastRenderTimestamp.Set(float64(time.Now().Unix()))
+	}
+}
@@ -0,0 +1,189 @@
+package promplot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// MetricsOption customizes a call to Metrics.
+type MetricsOption func(*metricsOptions)
+
+type metricsOptions struct {
+	maxPointsPerRequest int
+	concurrency         int
+	retry               func(attempt int) time.Duration
+}
+
+// WithMaxPointsPerRequest caps the number of data points requested per
+// query_range call. When duration/step would exceed it, Metrics splits
+// the range into contiguous sub-ranges and queries them separately,
+// keeping each request under Prometheus' own max-samples limit.
+func WithMaxPointsPerRequest(n int) MetricsOption {
+	return func(o *metricsOptions) {
+		o.maxPointsPerRequest = n
+	}
+}
+
+// WithConcurrency bounds how many sub-range requests run at once when a
+// query has been split. Defaults to 1, i.e. sub-ranges are queried one
+// at a time.
+func WithConcurrency(n int) MetricsOption {
+	return func(o *metricsOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithRetry retries a failed sub-range request, waiting backoff(attempt)
+// between attempts starting at attempt 0. Querying stops and the error
+// is returned once backoff returns a negative duration.
+func WithRetry(backoff func(attempt int) time.Duration) MetricsOption {
+	return func(o *metricsOptions) {
+		o.retry = backoff
+	}
+}
+
+// Metrics queries promServer for query over the range
+// [t.Add(-duration), t], requesting step data points.
+//
+// By default the whole range is requested in a single query_range call.
+// With WithMaxPointsPerRequest set lower than step, the range is split
+// into contiguous sub-ranges, queried concurrently up to
+// WithConcurrency, and stitched back together by matching each
+// returned series' label fingerprint. This lets callers pull a long
+// history at fine resolution without hitting Prometheus' "query
+// processing would load too many samples" limit.
+func Metrics(promServer, query string, t time.Time, duration time.Duration, step int, opts ...MetricsOption) (model.Matrix, error) {
+	o := metricsOptions{maxPointsPerRequest: step, concurrency: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxPointsPerRequest <= 0 {
+		o.maxPointsPerRequest = step
+	}
+	if o.concurrency <= 0 {
+		o.concurrency = 1
+	}
+
+	client, err := api.NewClient(api.Config{Address: promServer})
+	if err != nil {
+		return nil, fmt.Errorf("failed creating prometheus client: %v", err)
+	}
+	v1api := apiv1.NewAPI(client)
+
+	ranges := splitRange(t, duration, step, o.maxPointsPerRequest)
+	if len(ranges) == 1 {
+		return queryRange(v1api, query, ranges[0], o)
+	}
+
+	results := make([]model.Matrix, len(ranges))
+	errs := make([]error, len(ranges))
+
+	sem := make(chan struct{}, o.concurrency)
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		i, r := i, r
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = queryRange(v1api, query, r, o)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stitchMatrices(results), nil
+}
+
+// splitRange divides [t-duration, t] into contiguous sub-ranges of at
+// most maxPointsPerRequest points each, preserving the original step
+// interval. It returns a single range covering the whole period when
+// step already fits within maxPointsPerRequest.
+func splitRange(t time.Time, duration time.Duration, step, maxPointsPerRequest int) []apiv1.Range {
+	start := t.Add(-duration)
+	if step <= 0 {
+		return []apiv1.Range{{Start: start, End: t, Step: duration}}
+	}
+	interval := duration / time.Duration(step)
+
+	if step <= maxPointsPerRequest {
+		return []apiv1.Range{{Start: start, End: t, Step: interval}}
+	}
+
+	chunks := (step + maxPointsPerRequest - 1) / maxPointsPerRequest
+	chunkPoints := (step + chunks - 1) / chunks
+	chunkSpan := interval * time.Duration(chunkPoints)
+
+	var ranges []apiv1.Range
+	for s := start; !s.After(t); s = s.Add(chunkSpan).Add(interval) {
+		e := s.Add(chunkSpan)
+		if e.After(t) {
+			e = t
+		}
+		ranges = append(ranges, apiv1.Range{Start: s, End: e, Step: interval})
+	}
+	return ranges
+}
+
+// queryRange runs a single query_range call, retrying per o.retry on
+// failure.
+func queryRange(v1api apiv1.API, query string, r apiv1.Range, o metricsOptions) (model.Matrix, error) {
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		value, _, err := v1api.QueryRange(ctx, query, r)
+		cancel()
+		if err == nil {
+			matrix, ok := value.(model.Matrix)
+			if !ok {
+				return nil, fmt.Errorf("unexpected query_range result type %T", value)
+			}
+			return matrix, nil
+		}
+		if o.retry == nil {
+			return nil, fmt.Errorf("failed querying prometheus: %v", err)
+		}
+		wait := o.retry(attempt)
+		if wait < 0 {
+			return nil, fmt.Errorf("failed querying prometheus after %d attempt(s): %v", attempt+1, err)
+		}
+		time.Sleep(wait)
+	}
+}
+
+// stitchMatrices merges the sub-range results of a split query back
+// into one Matrix, concatenating the values of series that share the
+// same label fingerprint across sub-ranges.
+func stitchMatrices(results []model.Matrix) model.Matrix {
+	bySeries := map[model.Fingerprint]*model.SampleStream{}
+	var order []model.Fingerprint
+
+	for _, m := range results {
+		for _, sample := range m {
+			fp := sample.Metric.Fingerprint()
+			stream, ok := bySeries[fp]
+			if !ok {
+				stream = &model.SampleStream{Metric: sample.Metric}
+				bySeries[fp] = stream
+				order = append(order, fp)
+			}
+			stream.Values = append(stream.Values, sample.Values...)
+		}
+	}
+
+	matrix := make(model.Matrix, len(order))
+	for i, fp := range order {
+		matrix[i] = bySeries[fp]
+	}
+	return matrix
+}
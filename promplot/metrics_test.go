@@ -0,0 +1,79 @@
+package promplot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestSplitRangeNoSplit(t *testing.T) {
+	end := time.Unix(1000, 0)
+	ranges := splitRange(end, 100*time.Second, 100, 100)
+	if len(ranges) != 1 {
+		t.Fatalf("got %d ranges, want 1", len(ranges))
+	}
+	if got, want := ranges[0].Start, end.Add(-100*time.Second); !got.Equal(want) {
+		t.Errorf("start = %v, want %v", got, want)
+	}
+	if !ranges[0].End.Equal(end) {
+		t.Errorf("end = %v, want %v", ranges[0].End, end)
+	}
+}
+
+func TestSplitRangeNoGapOrOverlap(t *testing.T) {
+	end := time.Unix(1000, 0)
+	ranges := splitRange(end, 1000*time.Second, 1000, 300)
+	if len(ranges) < 2 {
+		t.Fatalf("got %d ranges, want at least 2", len(ranges))
+	}
+
+	if got, want := ranges[0].Start, end.Add(-1000*time.Second); !got.Equal(want) {
+		t.Errorf("first range start = %v, want %v", got, want)
+	}
+	if !ranges[len(ranges)-1].End.Equal(end) {
+		t.Errorf("last range end = %v, want %v", ranges[len(ranges)-1].End, end)
+	}
+
+	for i := 1; i < len(ranges); i++ {
+		prevEnd := ranges[i-1].End
+		wantStart := prevEnd.Add(ranges[i-1].Step)
+		if !ranges[i].Start.Equal(wantStart) {
+			t.Errorf("range %d starts at %v, want %v (prev end %v + step)", i, ranges[i].Start, wantStart, prevEnd)
+		}
+	}
+}
+
+func TestStitchMatricesConcatenatesWithoutDuplicates(t *testing.T) {
+	metric := model.Metric{"__name__": "up"}
+	a := model.Matrix{
+		&model.SampleStream{
+			Metric: metric,
+			Values: []model.SamplePair{
+				{Timestamp: 0, Value: 1},
+				{Timestamp: 60000, Value: 2},
+			},
+		},
+	}
+	b := model.Matrix{
+		&model.SampleStream{
+			Metric: metric,
+			Values: []model.SamplePair{
+				{Timestamp: 120000, Value: 3},
+			},
+		},
+	}
+
+	stitched := stitchMatrices([]model.Matrix{a, b})
+	if len(stitched) != 1 {
+		t.Fatalf("got %d series, want 1", len(stitched))
+	}
+	if got, want := len(stitched[0].Values), 3; got != want {
+		t.Fatalf("got %d values, want %d", got, want)
+	}
+	for i, ts := range []model.Time{0, 60000, 120000} {
+		if stitched[0].Values[i].Timestamp != ts {
+			t.Errorf("value %d timestamp = %v, want %v", i, stitched[0].Values[i].Timestamp, ts)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package promplot
 import (
 	"bytes"
 	"fmt"
+	"image/color"
 	"io"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/gonum/plot"
 	"github.com/gonum/plot/palette/brewer"
@@ -18,9 +20,77 @@ import (
 // Only show important part of metric name
 var labelText = regexp.MustCompile("\\{(.*)\\}")
 
+// PlotOption customizes a call to Plot.
+type PlotOption func(*plotOptions)
+
+type plotOptions struct {
+	highlight     bool
+	highlightFrom time.Time
+	highlightTo   time.Time
+}
+
+// HighlightRange shades the given time range on the plot, drawn behind
+// the data. Used by the scheduler to mark the window that triggered an
+// alert.
+func HighlightRange(from, to time.Time) PlotOption {
+	return func(o *plotOptions) {
+		o.highlight = true
+		o.highlightFrom = from
+		o.highlightTo = to
+	}
+}
+
 // Plot creates a plot from metric data and saves it to a temporary file.
 // It's the callers responsibility to remove the returned file when no longer needed.
-func Plot(metrics model.Matrix, title, format string) (io.Reader, error) {
+func Plot(metrics model.Matrix, title, format string, opts ...PlotOption) (io.Reader, error) {
+	var o plotOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p, err := newStyledPlot(title)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.highlight {
+		if err := addHighlight(p, metrics, o.highlightFrom, o.highlightTo); err != nil {
+			return nil, err
+		}
+	}
+
+	series, err := addLines(p, metrics, len(metrics) > 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Draw plot in canvas with margin
+	margin := 6 * vg.Millimeter
+	width := 24 * vg.Centimeter
+	height := 20 * vg.Centimeter
+	c, err := draw.NewFormattedCanvas(width, height, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating image canvas: %v", err)
+	}
+	cc := draw.Crop(draw.New(c), margin, -margin, margin, -margin)
+	p.Draw(cc)
+
+	b := new(bytes.Buffer)
+	if _, err = c.WriteTo(b); err != nil {
+		return nil, fmt.Errorf("failed saving plot: %v", err)
+	}
+
+	if format == "svg" {
+		return bytes.NewReader(addSVGTooltips(b.Bytes(), p, cc, height, series)), nil
+	}
+
+	return b, nil
+}
+
+// newStyledPlot creates an empty plot with the fonts, time axis and
+// legend positioning shared by every promplot chart, single-panel or
+// dashboard.
+func newStyledPlot(title string) (*plot.Plot, error) {
 	p, err := plot.New()
 	if err != nil {
 		return nil, fmt.Errorf("failed creating new plot: %v", err)
@@ -45,7 +115,14 @@ func Plot(metrics model.Matrix, title, format string) (io.Reader, error) {
 	p.Legend.Top = true
 	p.Legend.YOffs = 15 * vg.Millimeter
 
-	// Color palette for drawing lines
+	return p, nil
+}
+
+// addLines adds one line per sample in metrics to p, cycling through the
+// shared color palette, and returns the per-series data needed to
+// annotate SVG output afterwards. The legend is only populated when
+// showLegend is set, since a single-series plot doesn't need one.
+func addLines(p *plot.Plot, metrics model.Matrix, showLegend bool) ([]svgSeries, error) {
 	paletteSize := 8
 	palette, err := brewer.GetPalette(brewer.TypeAny, "Dark2", paletteSize)
 	if err != nil {
@@ -53,6 +130,7 @@ func Plot(metrics model.Matrix, title, format string) (io.Reader, error) {
 	}
 	colors := palette.Colors()
 
+	var series []svgSeries
 	for s, sample := range metrics {
 		data := make(plotter.XYs, len(sample.Values))
 		for i, v := range sample.Values {
@@ -72,28 +150,66 @@ func Plot(metrics model.Matrix, title, format string) (io.Reader, error) {
 		l.LineStyle.Color = colors[s%paletteSize]
 
 		p.Add(l)
-		if len(metrics) > 1 {
-			m := labelText.FindStringSubmatch(sample.Metric.String())
-			if m != nil {
-				p.Legend.Add(m[1], l)
-			}
+		label := sample.Metric.String()
+		if m := labelText.FindStringSubmatch(label); m != nil {
+			label = m[1]
 		}
+		if showLegend {
+			p.Legend.Add(label, l)
+		}
+		series = append(series, svgSeries{label: label, data: data})
 	}
 
-	// Draw plot in canvas with margin
-	margin := 6 * vg.Millimeter
-	width := 24 * vg.Centimeter
-	height := 20 * vg.Centimeter
-	c, err := draw.NewFormattedCanvas(width, height, format)
-	if err != nil {
-		return nil, fmt.Errorf("failed creating image canvas: %v", err)
+	return series, nil
+}
+
+// addHighlight adds a shaded rectangle spanning [from, to] and the
+// y-range of metrics, so it sits behind the plotted lines and highlights
+// the time range an alert fired over.
+func addHighlight(p *plot.Plot, metrics model.Matrix, from, to time.Time) error {
+	minY, maxY, ok := dataYRange(metrics)
+	if !ok {
+		return nil
+	}
+	pad := (maxY - minY) * 0.05
+	if pad == 0 {
+		pad = 1
 	}
-	p.Draw(draw.Crop(draw.New(c), margin, -margin, margin, -margin))
 
-	b := new(bytes.Buffer)
-	if _, err = c.WriteTo(b); err != nil {
-		return nil, fmt.Errorf("failed saving plot: %v", err)
+	area := plotter.XYs{
+		{X: float64(from.Unix()), Y: minY - pad},
+		{X: float64(to.Unix()), Y: minY - pad},
+		{X: float64(to.Unix()), Y: maxY + pad},
+		{X: float64(from.Unix()), Y: maxY + pad},
 	}
+	poly, err := plotter.NewPolygon(area)
+	if err != nil {
+		return fmt.Errorf("failed creating highlight: %v", err)
+	}
+	poly.Color = color.RGBA{R: 255, A: 64}
+	poly.LineStyle.Width = 0
 
-	return b, nil
+	p.Add(poly)
+	return nil
+}
+
+// dataYRange returns the min/max Y value across every sample in
+// metrics. ok is false when metrics has no values to range over.
+func dataYRange(metrics model.Matrix) (min, max float64, ok bool) {
+	for _, sample := range metrics {
+		for _, v := range sample.Values {
+			f, err := strconv.ParseFloat(v.Value.String(), 64)
+			if err != nil {
+				continue
+			}
+			if !ok || f < min {
+				min = f
+			}
+			if !ok || f > max {
+				max = f
+			}
+			ok = true
+		}
+	}
+	return min, max, ok
 }
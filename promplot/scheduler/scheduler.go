@@ -0,0 +1,251 @@
+// Package scheduler runs a set of PromQL queries on a recurring
+// interval and delivers a plot to the configured sinks only when a
+// job's alert condition fires: a threshold crossing, an absent()
+// query, or any boolean vector returning a non-empty result.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/common/model"
+	yaml "gopkg.in/yaml.v2"
+
+	"qvl.io/promplot/promplot"
+	"qvl.io/promplot/promplot/sinks"
+)
+
+// Number of data points requested when rendering a job's plot.
+const jobStep = 100
+
+// JobConfig describes one recurring query to evaluate and, when it
+// fires, plot and deliver.
+type JobConfig struct {
+	Name  string `yaml:"name" json:"name"`
+	Query string `yaml:"query" json:"query"`
+	Range string `yaml:"range" json:"range"`
+	// Interval between evaluations, e.g. "5m".
+	Interval string `yaml:"interval" json:"interval"`
+	// AlertQuery is evaluated to decide whether the job is firing.
+	// Defaults to Query. Use this when the plotted query isn't itself
+	// boolean, e.g. plotting a rate() while alerting on a absent() or a
+	// comparison against Threshold.
+	AlertQuery string `yaml:"alert_query,omitempty" json:"alert_query,omitempty"`
+	// Threshold and Operator (<, <=, >, >=, ==) compare the latest value
+	// of AlertQuery. When Threshold is nil, the job fires whenever
+	// AlertQuery returns any series, covering absent() and plain boolean
+	// vector alerts.
+	Threshold *float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+	Operator  string   `yaml:"operator,omitempty" json:"operator,omitempty"`
+	Format    string   `yaml:"format,omitempty" json:"format,omitempty"`
+	Sinks     []string `yaml:"sinks" json:"sinks"`
+}
+
+// Config is the top-level schedule config: the jobs to run.
+type Config struct {
+	Jobs []JobConfig `yaml:"jobs" json:"jobs"`
+}
+
+// ParseConfig parses a schedule config in YAML (or JSON, which is valid
+// YAML) format.
+func ParseConfig(data []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed parsing schedule config: %v", err)
+	}
+	if len(cfg.Jobs) == 0 {
+		return Config{}, fmt.Errorf("schedule config has no jobs")
+	}
+	return cfg, nil
+}
+
+// Scheduler evaluates a set of jobs on their configured intervals and
+// delivers plots to sinks when a job's alert condition fires.
+type Scheduler struct {
+	PromServer string
+	Jobs       []JobConfig
+	State      *State
+	// Logger receives progress messages. Optional, defaults to discarding
+	// all output.
+	Logger log.Logger
+}
+
+// Run evaluates every job on its own ticker until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, job := range s.Jobs {
+		interval, err := time.ParseDuration(job.Interval)
+		if err != nil {
+			return fmt.Errorf("job %q: invalid interval: %v", job.Name, err)
+		}
+
+		job := job
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runJob(ctx, job, interval)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job JobConfig, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.evaluate(job, interval); err != nil {
+			level.Error(s.logger()).Log("msg", "job evaluation failed", "job", job.Name, "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluate runs job's alert query, compares it to the last known state
+// and, on a transition into or out of firing, renders and delivers a
+// plot highlighting the offending time range. State is persisted after
+// every evaluation so a restart doesn't re-notify about a transition it
+// already handled.
+func (s *Scheduler) evaluate(job JobConfig, interval time.Duration) error {
+	now := time.Now()
+	duration, err := promplot.ParseRange(job.Range)
+	if err != nil {
+		return fmt.Errorf("invalid range: %v", err)
+	}
+
+	firing, err := s.isFiring(job, now)
+	if err != nil {
+		return fmt.Errorf("failed evaluating alert: %v", err)
+	}
+
+	prev := s.State.Get(job.Name)
+	switch {
+	case firing && !prev.Firing:
+		level.Info(s.logger()).Log("msg", "alert firing", "job", job.Name)
+		// The crossing happened sometime since the last evaluation; that's
+		// the best window we have to highlight.
+		if err := s.notify(job, now, duration, true, now.Add(-interval), now); err != nil {
+			return err
+		}
+		s.State.Set(job.Name, JobState{Firing: true, FiredAt: now})
+	case !firing && prev.Firing:
+		level.Info(s.logger()).Log("msg", "alert resolved", "job", job.Name)
+		if err := s.notify(job, now, duration, false, prev.FiredAt, now); err != nil {
+			return err
+		}
+		s.State.Set(job.Name, JobState{Firing: false, FiredAt: prev.FiredAt})
+	}
+
+	return s.State.Save()
+}
+
+func (s *Scheduler) isFiring(job JobConfig, now time.Time) (bool, error) {
+	query := job.AlertQuery
+	if query == "" {
+		query = job.Query
+	}
+
+	queryStart := time.Now()
+	metrics, err := promplot.Metrics(s.PromServer, query, now, time.Minute, 1)
+	promplot.ObserveQuery(err)
+	if err != nil {
+		return false, err
+	}
+	level.Debug(s.logger()).Log("msg", "alert query done", "job", job.Name, "query", query, "series", len(metrics), "duration_ms", time.Since(queryStart).Milliseconds())
+	if len(metrics) == 0 {
+		return false, nil
+	}
+	if job.Threshold == nil {
+		return true, nil
+	}
+	return compareThreshold(latestValue(metrics), job.Operator, *job.Threshold), nil
+}
+
+func (s *Scheduler) notify(job JobConfig, now time.Time, duration time.Duration, firing bool, highlightStart, highlightEnd time.Time) error {
+	queryStart := time.Now()
+	metrics, err := promplot.Metrics(s.PromServer, job.Query, now, duration, jobStep)
+	promplot.ObserveQuery(err)
+	if err != nil {
+		return fmt.Errorf("failed getting metrics: %v", err)
+	}
+	level.Debug(s.logger()).Log("msg", "query done", "job", job.Name, "query", job.Query, "series", len(metrics), "duration_ms", time.Since(queryStart).Milliseconds())
+
+	title := job.Name
+	var opts []promplot.PlotOption
+	if firing {
+		title = "[ALERT] " + title
+	} else {
+		title = "[RESOLVED] " + title
+	}
+	opts = append(opts, promplot.HighlightRange(highlightStart, highlightEnd))
+
+	format := job.Format
+	if format == "" {
+		format = "png"
+	}
+	renderStart := time.Now()
+	plot, err := promplot.Plot(metrics, title, format, opts...)
+	promplot.ObserveRender(renderStart, err)
+	if err != nil {
+		return fmt.Errorf("failed creating plot: %v", err)
+	}
+	level.Debug(s.logger()).Log("msg", "render done", "job", job.Name, "duration_ms", time.Since(renderStart).Milliseconds())
+
+	dests := make([]sinks.Sink, len(job.Sinks))
+	for i, raw := range job.Sinks {
+		dests[i], err = sinks.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("failed configuring sink: %v", err)
+		}
+	}
+
+	return sinks.SendAll(s.logger(), dests, title, plot)
+}
+
+// logger returns s.Logger, falling back to a no-op logger so callers
+// don't need to nil-check before logging.
+func (s *Scheduler) logger() log.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return log.NewNopLogger()
+}
+
+func latestValue(metrics model.Matrix) float64 {
+	var v float64
+	for _, sample := range metrics {
+		if len(sample.Values) == 0 {
+			continue
+		}
+		if f, err := strconv.ParseFloat(sample.Values[len(sample.Values)-1].Value.String(), 64); err == nil {
+			v = f
+		}
+	}
+	return v
+}
+
+func compareThreshold(value float64, op string, threshold float64) bool {
+	switch op {
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case ">=":
+		return value >= threshold
+	case "==":
+		return value == threshold
+	default:
+		return value > threshold
+	}
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// JobState is what's persisted per job so a scheduler restart doesn't
+// immediately re-fire alerts it already notified about.
+type JobState struct {
+	Firing  bool      `json:"firing"`
+	FiredAt time.Time `json:"fired_at"`
+}
+
+// State is a small on-disk store of JobState keyed by job name.
+type State struct {
+	path string
+	mu   sync.Mutex
+	jobs map[string]JobState
+}
+
+// LoadState reads job state from path. A missing file is treated as an
+// empty, fresh state.
+func LoadState(path string) (*State, error) {
+	s := &State{path: path, jobs: map[string]JobState{}}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed reading state file: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.jobs); err != nil {
+		return nil, fmt.Errorf("failed parsing state file: %v", err)
+	}
+	return s, nil
+}
+
+// Get returns the last known state for job, or a zero value if job
+// hasn't fired before.
+func (s *State) Get(job string) JobState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[job]
+}
+
+// Set records the in-memory state for job. Call Save to persist it.
+func (s *State) Set(job string, js JobState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job] = js
+}
+
+// Save writes the current state to disk.
+func (s *State) Save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.jobs, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed encoding state: %v", err)
+	}
+	if err := ioutil.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed writing state file: %v", err)
+	}
+	return nil
+}
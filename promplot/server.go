@@ -0,0 +1,172 @@
+package promplot
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Number of data points to request for a graph served over HTTP.
+const serverStep = 100
+
+// indexPage is a minimal form letting users try out PromQL queries
+// and see the resulting chart without leaving the browser.
+const indexPage = `<!DOCTYPE html>
+<html>
+<head><title>promplot</title></head>
+<body>
+	<h1>promplot</h1>
+	<form action="/graph" method="get">
+		<input type="text" name="query" placeholder="PromQL query" size="50">
+		<input type="text" name="range" placeholder="5h" value="1h">
+		<input type="text" name="title" placeholder="Title">
+		<select name="format">
+			<option value="svg">svg</option>
+			<option value="png">png</option>
+			<option value="pdf">pdf</option>
+		</select>
+		<button type="submit">Plot</button>
+	</form>
+</body>
+</html>
+`
+
+// contentTypes maps the formats accepted by Plot to their HTTP content type.
+var contentTypes = map[string]string{
+	"svg":  "image/svg+xml",
+	"png":  "image/png",
+	"pdf":  "application/pdf",
+	"eps":  "application/postscript",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"tiff": "image/tiff",
+}
+
+// NewServer creates an http.Handler serving rendered plots for ad-hoc
+// PromQL queries against promServer.
+//
+// Routes:
+//
+//	/         an HTML form for trying out queries
+//	/graph    renders a plot; query params: query, range, time, title, format
+//	/metrics  promplot's own Prometheus instrumentation
+//
+// Each request is served independently, so multiple queries can be
+// in flight concurrently.
+func NewServer(promServer string, logger log.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", serveIndex)
+	mux.HandleFunc("/graph", serveGraph(promServer, logger))
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+func serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, indexPage)
+}
+
+func serveGraph(promServer string, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		if query == "" {
+			http.Error(w, "missing required parameter: query", http.StatusBadRequest)
+			return
+		}
+
+		rangeParam := r.URL.Query().Get("range")
+		if rangeParam == "" {
+			rangeParam = "1h"
+		}
+		duration, err := ParseRange(rangeParam)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid range: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		queryTime := time.Now()
+		if t := r.URL.Query().Get("time"); t != "" {
+			unix, err := strconv.ParseInt(t, 10, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid time: %v", err), http.StatusBadRequest)
+				return
+			}
+			queryTime = time.Unix(unix, 0)
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "svg"
+		}
+		contentType, ok := contentTypes[format]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unsupported format: %s", format), http.StatusBadRequest)
+			return
+		}
+
+		title := r.URL.Query().Get("title")
+		if title == "" {
+			title = query
+		}
+
+		queryStart := time.Now()
+		metrics, err := Metrics(promServer, query, queryTime, duration, serverStep)
+		ObserveQuery(err)
+		if err != nil {
+			level.Error(logger).Log("msg", "failed getting metrics", "query", query, "err", err)
+			http.Error(w, fmt.Sprintf("failed getting metrics: %v", err), http.StatusBadGateway)
+			return
+		}
+		level.Debug(logger).Log("msg", "query done", "query", query, "series", len(metrics), "duration_ms", time.Since(queryStart).Milliseconds())
+
+		renderStart := time.Now()
+		plot, err := Plot(metrics, title, format)
+		ObserveRender(renderStart, err)
+		if err != nil {
+			level.Error(logger).Log("msg", "failed creating plot", "query", query, "err", err)
+			http.Error(w, fmt.Sprintf("failed creating plot: %v", err), http.StatusInternalServerError)
+			return
+		}
+		level.Debug(logger).Log("msg", "render done", "query", query, "duration_ms", time.Since(renderStart).Milliseconds())
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=30")
+		if _, err := io.Copy(w, plot); err != nil {
+			// Headers are already sent at this point, nothing more we can do.
+			return
+		}
+	}
+}
+
+// ParseRange parses a duration string, supporting an additional "d" unit
+// for days on top of what time.ParseDuration understands (e.g. "5d12h34m").
+func ParseRange(s string) (time.Duration, error) {
+	days := 0 * time.Hour
+	if i := strings.IndexByte(s, 'd'); i != -1 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid day component %q: %v", s[:i], err)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		s = s[i+1:]
+	}
+	if s == "" {
+		return days, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return days + d, nil
+}
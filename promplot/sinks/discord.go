@@ -0,0 +1,55 @@
+package sinks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// Discord posts the plot to a Discord incoming webhook.
+// Configured as discord://webhook-id/webhook-token.
+type Discord struct {
+	WebhookURL string
+}
+
+func newDiscord(u *url.URL) (Sink, error) {
+	if u.Host == "" || u.Path == "" || u.Path == "/" {
+		return nil, fmt.Errorf("discord sink needs discord://webhook-id/webhook-token")
+	}
+	return Discord{WebhookURL: fmt.Sprintf("https://discord.com/api/webhooks/%s%s", u.Host, u.Path)}, nil
+}
+
+// Kind identifies this sink as "discord".
+func (d Discord) Kind() string { return "discord" }
+
+// Send posts plot as a file attachment, using title as the message content.
+func (d Discord) Send(title string, plot io.Reader) error {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("content", title); err != nil {
+		return fmt.Errorf("failed building discord payload: %v", err)
+	}
+	part, err := w.CreateFormFile("file", "plot")
+	if err != nil {
+		return fmt.Errorf("failed building discord payload: %v", err)
+	}
+	if _, err := io.Copy(part, plot); err != nil {
+		return fmt.Errorf("failed building discord payload: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed building discord payload: %v", err)
+	}
+
+	resp, err := http.Post(d.WebhookURL, w.FormDataContentType(), body)
+	if err != nil {
+		return fmt.Errorf("failed posting to discord: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("discord webhook returned %s", resp.Status)
+	}
+	return nil
+}
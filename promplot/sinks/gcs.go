@@ -0,0 +1,47 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/storage"
+)
+
+// GCS uploads the plot as an object to a Google Cloud Storage bucket,
+// using application default credentials. Configured as gcs://bucket/prefix.
+type GCS struct {
+	Bucket string
+	Prefix string
+}
+
+func newGCS(u *url.URL) (Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("gcs sink needs gcs://bucket/prefix")
+	}
+	return GCS{Bucket: u.Host, Prefix: strings.TrimPrefix(u.Path, "/")}, nil
+}
+
+// Kind identifies this sink as "gcs".
+func (g GCS) Kind() string { return "gcs" }
+
+// Send uploads plot to the bucket, keyed by title and the current time.
+func (g GCS) Send(title string, plot io.Reader) error {
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed creating gcs client: %v", err)
+	}
+	defer client.Close()
+
+	w := client.Bucket(g.Bucket).Object(objectKey(g.Prefix, title)).NewWriter(ctx)
+	if _, err := io.Copy(w, plot); err != nil {
+		return fmt.Errorf("failed uploading to gcs: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed uploading to gcs: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mattermost uploads the plot via the Mattermost REST API and posts it
+// to a channel. Incoming webhooks can't carry attachments, so this talks
+// to the API directly using a bot/personal access token.
+// Configured as mattermost://TOKEN@host/channel-id.
+type Mattermost struct {
+	ServerURL string
+	Token     string
+	ChannelID string
+}
+
+func newMattermost(u *url.URL) (Sink, error) {
+	channelID := strings.TrimPrefix(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || u.Host == "" || channelID == "" {
+		return nil, fmt.Errorf("mattermost sink needs mattermost://TOKEN@host/channel-id")
+	}
+	return Mattermost{ServerURL: "https://" + u.Host, Token: u.User.Username(), ChannelID: channelID}, nil
+}
+
+// Kind identifies this sink as "mattermost".
+func (m Mattermost) Kind() string { return "mattermost" }
+
+// Send uploads plot and posts it to the configured channel with title as
+// the message text.
+func (m Mattermost) Send(title string, plot io.Reader) error {
+	fileID, err := m.uploadFile(title, plot)
+	if err != nil {
+		return err
+	}
+	return m.createPost(title, fileID)
+}
+
+func (m Mattermost) uploadFile(title string, plot io.Reader) (string, error) {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("channel_id", m.ChannelID); err != nil {
+		return "", fmt.Errorf("failed building mattermost upload: %v", err)
+	}
+	part, err := w.CreateFormFile("files", title+".png")
+	if err != nil {
+		return "", fmt.Errorf("failed building mattermost upload: %v", err)
+	}
+	if _, err := io.Copy(part, plot); err != nil {
+		return "", fmt.Errorf("failed building mattermost upload: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed building mattermost upload: %v", err)
+	}
+
+	resp, err := m.do("POST", "/api/v4/files", w.FormDataContentType(), body)
+	if err != nil {
+		return "", fmt.Errorf("failed uploading to mattermost: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		FileInfos []struct {
+			ID string `json:"id"`
+		} `json:"file_infos"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed parsing mattermost upload response: %v", err)
+	}
+	if len(result.FileInfos) == 0 {
+		return "", fmt.Errorf("mattermost upload returned no file")
+	}
+	return result.FileInfos[0].ID, nil
+}
+
+func (m Mattermost) createPost(title, fileID string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"channel_id": m.ChannelID,
+		"message":    title,
+		"file_ids":   []string{fileID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed building mattermost post: %v", err)
+	}
+	resp, err := m.do("POST", "/api/v4/posts", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed posting to mattermost: %v", err)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+func (m Mattermost) do(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, m.ServerURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+m.Token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("mattermost returned %s", resp.Status)
+	}
+	return resp, nil
+}
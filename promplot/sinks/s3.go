@@ -0,0 +1,46 @@
+package sinks
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3 uploads the plot as an object to an S3 bucket, using the default
+// AWS credential chain. Configured as s3://bucket/prefix.
+type S3 struct {
+	Bucket string
+	Prefix string
+}
+
+func newS3(u *url.URL) (Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("s3 sink needs s3://bucket/prefix")
+	}
+	return S3{Bucket: u.Host, Prefix: strings.TrimPrefix(u.Path, "/")}, nil
+}
+
+// Kind identifies this sink as "s3".
+func (s S3) Kind() string { return "s3" }
+
+// Send uploads plot to the bucket, keyed by title and the current time.
+func (s S3) Send(title string, plot io.Reader) error {
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed creating aws session: %v", err)
+	}
+	_, err = s3manager.NewUploader(sess).Upload(&s3manager.UploadInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(objectKey(s.Prefix, title)),
+		Body:   plot,
+	})
+	if err != nil {
+		return fmt.Errorf("failed uploading to s3: %v", err)
+	}
+	return nil
+}
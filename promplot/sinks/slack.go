@@ -0,0 +1,41 @@
+package sinks
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/nlopes/slack"
+)
+
+// Slack uploads the plot as a file to a Slack channel.
+// Configured as slack://TOKEN@channel.
+type Slack struct {
+	Token   string
+	Channel string
+}
+
+func newSlack(u *url.URL) (Sink, error) {
+	if u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("slack sink needs slack://TOKEN@channel")
+	}
+	return Slack{Token: u.User.Username(), Channel: u.Host}, nil
+}
+
+// Kind identifies this sink as "slack".
+func (s Slack) Kind() string { return "slack" }
+
+// Send uploads plot as a Slack file titled title to the configured channel.
+func (s Slack) Send(title string, plot io.Reader) error {
+	api := slack.New(s.Token)
+	_, err := api.UploadFile(slack.FileUploadParameters{
+		Title:    title,
+		Filetype: "auto",
+		Channels: []string{s.Channel},
+		Reader:   plot,
+	})
+	if err != nil {
+		return fmt.Errorf("failed uploading to slack: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package sinks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// Telegram posts the plot as a photo to a chat via a Telegram bot.
+// Configured as telegram://BOT_TOKEN@chat-id. A bot token itself
+// contains a colon (<id>:<hash>). It can be percent-encoded in the URL
+// (the ':' as %3A) or left as is, in which case it's parsed as separate
+// userinfo username/password fields; either way newTelegram reassembles
+// the full token.
+type Telegram struct {
+	Token  string
+	ChatID string
+}
+
+func newTelegram(u *url.URL) (Sink, error) {
+	if u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("telegram sink needs telegram://BOT_TOKEN@chat-id")
+	}
+	token := u.User.Username()
+	if password, ok := u.User.Password(); ok {
+		token = token + ":" + password
+	}
+	return Telegram{Token: token, ChatID: u.Host}, nil
+}
+
+// Kind identifies this sink as "telegram".
+func (t Telegram) Kind() string { return "telegram" }
+
+// Send posts plot as a photo with title as the caption.
+func (t Telegram) Send(title string, plot io.Reader) error {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	if err := w.WriteField("chat_id", t.ChatID); err != nil {
+		return fmt.Errorf("failed building telegram payload: %v", err)
+	}
+	if err := w.WriteField("caption", title); err != nil {
+		return fmt.Errorf("failed building telegram payload: %v", err)
+	}
+	part, err := w.CreateFormFile("photo", "plot.png")
+	if err != nil {
+		return fmt.Errorf("failed building telegram payload: %v", err)
+	}
+	if _, err := io.Copy(part, plot); err != nil {
+		return fmt.Errorf("failed building telegram payload: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed building telegram payload: %v", err)
+	}
+
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendPhoto", t.Token)
+	resp, err := http.Post(url, w.FormDataContentType(), body)
+	if err != nil {
+		return fmt.Errorf("failed posting to telegram: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telegram bot api returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package promplot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+)
+
+// svgSeries holds what's needed to annotate one plotted line with hover
+// tooltips in the SVG output.
+type svgSeries struct {
+	label string
+	data  plotter.XYs
+}
+
+var xmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&quot;",
+)
+
+// addSVGTooltips post-processes a rendered SVG plot, injecting a <title>
+// element for every plotted data point (rendered as a tiny transparent
+// circle so SVG viewers show it as a hover tooltip) and a <g id="legend">
+// group listing the series labels. This gives users something closer to
+// Grafana-style inspection, without any JavaScript, when the SVG is
+// embedded in a dashboard or served by the HTTP graph server.
+func addSVGTooltips(svg []byte, p *plot.Plot, c draw.Canvas, height vg.Length, series []svgSeries) []byte {
+	dc := p.DataCanvas(c)
+	x, y := p.Transforms(&dc)
+	heightPts := height.Points()
+
+	const (
+		legendX         = 8.0
+		legendLineStart = 14.0
+		legendLineStep  = 14.0
+	)
+
+	var extra bytes.Buffer
+	extra.WriteString(`<g id="legend">` + "\n")
+	for i, s := range series {
+		ly := legendLineStart + float64(i)*legendLineStep
+		fmt.Fprintf(&extra, `<text x="%.2f" y="%.2f">%s</text>`+"\n", legendX, ly, xmlEscaper.Replace(s.label))
+	}
+	extra.WriteString("</g>\n")
+
+	for _, s := range series {
+		extra.WriteString(`<g class="series">` + "\n")
+		fmt.Fprintf(&extra, "<title>%s</title>\n", xmlEscaper.Replace(s.label))
+		for _, pt := range s.data {
+			cx := x(pt.X).Points()
+			cy := heightPts - y(pt.Y).Points()
+			fmt.Fprintf(&extra,
+				`<circle cx="%.2f" cy="%.2f" r="3" fill-opacity="0" stroke="none"><title>%s</title></circle>`+"\n",
+				cx, cy, xmlEscaper.Replace(pointLabel(pt)),
+			)
+		}
+		extra.WriteString("</g>\n")
+	}
+
+	return bytes.Replace(svg, []byte("</svg>"), append(extra.Bytes(), []byte("</svg>")...), 1)
+}
+
+func pointLabel(pt plotter.XY) string {
+	return fmt.Sprintf("%s: %v", time.Unix(int64(pt.X), 0).Format("2006-01-02 15:04:05"), pt.Y)
+}